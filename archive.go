@@ -0,0 +1,244 @@
+package copy
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isArchive reports whether srcPath looks, by its extension, like an
+// archive that format should unpack.
+func isArchive(srcPath string, format UnpackArchiveFormat) bool {
+	isZip := strings.HasSuffix(srcPath, ".zip")
+	isTar := strings.HasSuffix(srcPath, ".tar") || strings.HasSuffix(srcPath, ".tar.gz") || strings.HasSuffix(srcPath, ".tgz")
+	switch format {
+	case UnpackZip:
+		return isZip
+	case UnpackTar:
+		return isTar
+	case UnpackAuto:
+		return isZip || isTar
+	default:
+		return false
+	}
+}
+
+// acopy extracts the archive at srcPath into destdir instead of copying the
+// archive file byte-for-byte. It guards against Zip-Slip by rejecting any
+// entry whose cleaned path would escape destdir, skips entries that match
+// toSkip (an entry or any of its ancestor directories), and routes
+// symlink entries through opt.OnSymlink.
+func acopy(src source, srcPath, destdir string, toSkip map[string]struct{}, opt Options) (err error) {
+	f, err := src.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer fclose(f, &err)
+
+	if strings.HasSuffix(srcPath, ".zip") {
+		return acopyZip(f, destdir, toSkip, opt)
+	}
+	gzipped := strings.HasSuffix(srcPath, ".tar.gz") || strings.HasSuffix(srcPath, ".tgz")
+	return acopyTar(f, destdir, gzipped, toSkip, opt)
+}
+
+func acopyZip(r io.Reader, destdir string, toSkip map[string]struct{}, opt Options) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return err
+	}
+
+	destdir = filepath.Clean(destdir)
+	for _, zf := range zr.File {
+		if archiveEntrySkipped(zf.Name, toSkip) {
+			continue
+		}
+		dest, err := safeJoin(destdir, zf.Name)
+		if err != nil {
+			return err
+		}
+		if zf.FileInfo().IsDir() {
+			if err := os.MkdirAll(dest, tmpPermissionForDirectory); err != nil {
+				return err
+			}
+			if err := os.Chmod(dest, zf.Mode()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipEntry(zf, dest, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipEntry(zf *zip.File, dest string, opt Options) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	if zf.Mode()&os.ModeSymlink != 0 {
+		switch onArchiveSymlink(opt, zf.Name) {
+		case Skip:
+			return nil
+		default: // Shallow (Deep falls back to Shallow inside an archive)
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			defer fclose(rc, &err)
+
+			target, err := io.ReadAll(rc)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(string(target), dest)
+		}
+	}
+
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer fclose(rc, &err)
+
+	w, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, zf.Mode())
+	if err != nil {
+		return err
+	}
+	defer fclose(w, &err)
+
+	if _, err = io.Copy(w, rc); err != nil {
+		return err
+	}
+	// OpenFile's mode is masked by umask, so set it explicitly to actually
+	// replicate the archive entry's permissions.
+	return os.Chmod(dest, zf.Mode())
+}
+
+func acopyTar(r io.Reader, destdir string, gzipped bool, toSkip map[string]struct{}, opt Options) (err error) {
+	if gzipped {
+		gr, gzErr := gzip.NewReader(r)
+		if gzErr != nil {
+			return gzErr
+		}
+		defer fclose(gr, &err)
+		r = gr
+	}
+
+	destdir = filepath.Clean(destdir)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if archiveEntrySkipped(hdr.Name, toSkip) {
+			continue
+		}
+
+		dest, err := safeJoin(destdir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, tmpPermissionForDirectory); err != nil {
+				return err
+			}
+			if err := os.Chmod(dest, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if onArchiveSymlink(opt, hdr.Name) == Skip {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, dest); err != nil {
+				return err
+			}
+		default:
+			if err := extractTarEntry(tr, dest, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractTarEntry(r io.Reader, dest string, mode os.FileMode) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	w, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer fclose(w, &err)
+
+	if _, err = io.Copy(w, r); err != nil {
+		return err
+	}
+	// OpenFile's mode is masked by umask, so set it explicitly to actually
+	// replicate the archive entry's permissions.
+	return os.Chmod(dest, mode)
+}
+
+// onArchiveSymlink resolves opt.OnSymlink for an archive entry. Deep has no
+// meaning inside an archive - there's no on-disk symlink to follow, only
+// another entry by name - so it falls back to Shallow rather than
+// silently ignoring the policy.
+func onArchiveSymlink(opt Options, name string) SymlinkAction {
+	onSymlink := opt.OnSymlink
+	if onSymlink == nil {
+		onSymlink = DefaultOptions.OnSymlink
+	}
+	if action := onSymlink(name); action != Deep {
+		return action
+	}
+	return Shallow
+}
+
+// archiveEntrySkipped reports whether name, or any of its ancestor
+// directories, is in toSkip - the same semantics copy() uses when it
+// checks toSkip on every directory level, which skips a directory's whole
+// subtree by skipping recursion into it.
+func archiveEntrySkipped(name string, toSkip map[string]struct{}) bool {
+	name = filepath.FromSlash(strings.TrimSuffix(name, "/"))
+	for name != "." && name != string(os.PathSeparator) && name != "" {
+		if _, skip := toSkip[name]; skip {
+			return true
+		}
+		name = filepath.Dir(name)
+	}
+	return false
+}
+
+// safeJoin joins destdir with an archive entry name, rejecting any entry
+// whose cleaned path would escape destdir (the "Zip-Slip" vulnerability).
+func safeJoin(destdir, name string) (string, error) {
+	destdir = filepath.Clean(destdir)
+	dest := filepath.Join(destdir, name)
+	if dest != destdir && !strings.HasPrefix(dest, destdir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("copy: illegal archive entry path %q escapes destination", name)
+	}
+	return dest, nil
+}
@@ -0,0 +1,97 @@
+package copy
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAcopy_Zip_TrailingSlashDest guards against the zip-slip check
+// rejecting every entry when dest has a trailing separator, since
+// filepath.Join(dest, name) then never looks like it has dest-plus-
+// separator as a prefix unless dest itself is cleaned first.
+func TestAcopy_Zip_TrailingSlashDest(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{"hello.txt": "hi"})
+
+	for _, name := range []string{"without-trailing-slash", "with-trailing-slash"} {
+		t.Run(name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "out")
+			if name == "with-trailing-slash" {
+				dest += string(os.PathSeparator)
+			}
+
+			err := Copy(zipPath, dest, Options{UnpackArchives: UnpackAuto})
+			if err != nil {
+				t.Fatalf("Copy returned error: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(filepath.Clean(dest), "hello.txt"))
+			if err != nil {
+				t.Fatalf("hello.txt not extracted: %v", err)
+			}
+			if string(got) != "hi" {
+				t.Errorf("hello.txt content = %q, want %q", got, "hi")
+			}
+		})
+	}
+}
+
+func TestAcopy_ZipSlip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "evil.zip")
+	writeZip(t, zipPath, map[string]string{"../escape.txt": "pwned"})
+
+	dest := filepath.Join(t.TempDir(), "out")
+	err := Copy(zipPath, dest, Options{UnpackArchives: UnpackAuto})
+	if err == nil {
+		t.Fatal("expected Copy to reject a path-escaping zip entry, got nil error")
+	}
+
+	if _, statErr := os.Stat(filepath.Join(filepath.Dir(dest), "escape.txt")); !os.IsNotExist(statErr) {
+		t.Fatalf("escape.txt must not be written outside dest, stat err = %v", statErr)
+	}
+}
+
+func TestAcopy_RespectsSkip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	writeZip(t, zipPath, map[string]string{
+		"keep.txt":   "keep",
+		"secret.txt": "secret",
+	})
+
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := CopyButSkipSome(zipPath, dest, []string{"secret.txt"}, Options{UnpackArchives: UnpackAuto}); err != nil {
+		t.Fatalf("CopyButSkipSome returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "keep.txt")); err != nil {
+		t.Errorf("expected keep.txt to be extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "secret.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected secret.txt to be skipped, stat err = %v", err)
+	}
+}
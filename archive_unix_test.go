@@ -0,0 +1,56 @@
+//go:build unix
+
+package copy
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// TestAcopy_Zip_PreservesModeDespiteUmask guards against OpenFile's mode
+// being silently masked by the process umask: without an explicit Chmod
+// after writing, a restrictive umask strips bits that were genuinely set
+// on the archive entry.
+func TestAcopy_Zip_PreservesModeDespiteUmask(t *testing.T) {
+	old := syscall.Umask(0o022)
+	defer syscall.Umask(old)
+
+	zipPath := filepath.Join(t.TempDir(), "archive.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+
+	hdr := &zip.FileHeader{Name: "run.sh"}
+	hdr.SetMode(0o777)
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("#!/bin/sh\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := Copy(zipPath, dest, Options{UnpackArchives: UnpackAuto}); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "run.sh"))
+	if err != nil {
+		t.Fatalf("run.sh not extracted: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o777 {
+		t.Errorf("run.sh mode = %o, want %o (umask should not have masked it)", got, 0o777)
+	}
+}
@@ -0,0 +1,61 @@
+package copy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopy_Concurrency_NestedTree(t *testing.T) {
+	src := t.TempDir()
+	want := map[string]string{}
+	for d := 0; d < 3; d++ {
+		dir := filepath.Join(src, fmt.Sprintf("dir%d", d), "nested")
+		for f := 0; f < 3; f++ {
+			rel := filepath.Join(fmt.Sprintf("dir%d", d), "nested", fmt.Sprintf("file%d.txt", f))
+			content := fmt.Sprintf("dir%d/file%d", d, f)
+			writeFile(t, filepath.Join(src, rel), content)
+			want[rel] = content
+		}
+		_ = dir
+	}
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	if err := Copy(src, dest, Options{Concurrency: 4}); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	for rel, content := range want {
+		got, err := os.ReadFile(filepath.Join(dest, rel))
+		if err != nil {
+			t.Fatalf("%s not copied: %v", rel, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s content = %q, want %q", rel, got, content)
+		}
+	}
+}
+
+func TestCopy_Concurrency_AbortsOnError(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "a.txt"), "a")
+	writeFile(t, filepath.Join(src, "b.txt"), "b")
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	boom := fmt.Errorf("boom")
+	opt := Options{
+		Concurrency: 2,
+		PreCallback: func(src, dest string, info os.FileInfo) error {
+			if filepath.Base(src) == "b.txt" {
+				return boom
+			}
+			return nil
+		},
+	}
+
+	err := Copy(src, dest, opt)
+	if err == nil {
+		t.Fatal("expected Copy to return an error")
+	}
+}
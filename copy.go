@@ -1,12 +1,19 @@
 package copy
 
 import (
+	"context"
+	"errors"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
+// ErrSkip, returned by an Options.PreCallback, tells copy to silently skip
+// the current entry (and, for a directory, its whole subtree) instead of
+// copying it.
+var ErrSkip = errors.New("skip")
+
 const (
 	// tmpPermissionForDirectory makes the destination directory writable,
 	// so that stuff can be copied recursively even if any original directory is NOT writable.
@@ -27,36 +34,58 @@ func CopyButSkipSome(src, dest string, toSkip []string, opt ...Options) error {
 	}
 
 	opt = append(opt, DefaultOptions)
-	info, err := os.Lstat(src)
+	osSrc := osSource{}
+	info, err := osSrc.Lstat(src)
 	if err != nil {
 		return err
 	}
 
-	return copy(src, dest, toSkipMap, info, opt[0])
+	sym := newSymlinkState(opt[0].MaxSymlinkDepth)
+	return copy(osSrc, src, dest, toSkipMap, sym, info, opt[0])
 }
 
 // copy dispatches copy-funcs according to the mode.
 // Because this "copy" could be called recursively,
 // "info" MUST be given here, NOT nil.
-func copy(src, dest string, toSkip map[string]struct{}, info os.FileInfo, opt Options) error {
-	if _, isToSkip := toSkip[src]; isToSkip {
+func copy(src source, srcPath, dest string, toSkip map[string]struct{}, sym *symlinkState, info os.FileInfo, opt Options) error {
+	if _, isToSkip := toSkip[srcPath]; isToSkip {
 		return nil
 	}
 
-	if info.Mode()&os.ModeSymlink != 0 {
-		return onsymlink(src, dest, info, opt)
+	if opt.PreCallback != nil {
+		if err := opt.PreCallback(srcPath, dest, info); err != nil {
+			if err == ErrSkip {
+				return nil
+			}
+			return err
+		}
 	}
 
-	if info.IsDir() {
-		return dcopy(src, dest, toSkip, info, opt)
+	var err error
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		err = onsymlink(src, srcPath, dest, sym, info, opt)
+	case info.IsDir():
+		err = dcopy(src, srcPath, dest, toSkip, sym, info, opt)
+	case opt.UnpackArchives != UnpackDisabled && isArchive(srcPath, opt.UnpackArchives):
+		err = acopy(src, srcPath, dest, toSkip, opt)
+	default:
+		err = fcopy(src, srcPath, dest, info, opt)
 	}
-	return fcopy(src, dest, info)
+	if err != nil {
+		return err
+	}
+
+	if opt.PostCallback != nil {
+		return opt.PostCallback(srcPath, dest, info)
+	}
+	return nil
 }
 
 // fcopy is for just a file,
 // with considering existence of parent directory
 // and file permission.
-func fcopy(src, dest string, info os.FileInfo) (err error) {
+func fcopy(src source, srcPath, dest string, info os.FileInfo, opt Options) (err error) {
 
 	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
 		return err
@@ -72,20 +101,35 @@ func fcopy(src, dest string, info os.FileInfo) (err error) {
 		return err
 	}
 
-	s, err := os.Open(src)
+	s, err := src.Open(srcPath)
 	if err != nil {
 		return err
 	}
 	defer fclose(s, &err)
 
-	_, err = io.Copy(f, s)
-	return err
+	if opt.BufferSize > 0 {
+		// Hide io.ReaderFrom and io.WriterTo, which would make
+		// io.CopyBuffer ignore the buffer.
+		_, err = io.CopyBuffer(struct{ io.Writer }{f}, struct{ io.Reader }{s}, make([]byte, opt.BufferSize))
+	} else {
+		_, err = io.Copy(f, s)
+	}
+	if err != nil {
+		return err
+	}
+	return preserve(srcPath, dest, info, opt)
 }
 
 // dcopy is for a directory,
 // with scanning contents inside the directory
-// and pass everything to "copy" recursively.
-func dcopy(srcdir, destdir string, toSkip map[string]struct{}, info os.FileInfo, opt Options) (err error) {
+// and pass everything to "copy" recursively. When opt.Concurrency > 0, all
+// of a directory's entries - files, symlinks, and subdirectories alike -
+// are fanned out across a worker pool. A subdirectory entry's own dest
+// directory is always created synchronously, as the first thing dcopy does
+// for it, before that subdirectory's contents are themselves read and
+// fanned out - so a parent directory is guaranteed to exist before any of
+// its children, even though sibling subtrees run concurrently.
+func dcopy(src source, srcdir, destdir string, toSkip map[string]struct{}, sym *symlinkState, info os.FileInfo, opt Options) (err error) {
 
 	originalMode := info.Mode()
 
@@ -93,44 +137,125 @@ func dcopy(srcdir, destdir string, toSkip map[string]struct{}, info os.FileInfo,
 	if err := os.MkdirAll(destdir, tmpPermissionForDirectory); err != nil {
 		return err
 	}
-	// Recover dir mode with original one.
-	defer chmod(destdir, originalMode, &err)
+	// Recover dir mode (and, if requested, other metadata) with the
+	// original one. This only fires once every descendant below -
+	// including ones handled by the worker pool - has finished, since both
+	// branches below block until all work completes.
+	defer func() {
+		chmod(destdir, originalMode, &err)
+		if perr := preserve(srcdir, destdir, info, opt); err == nil {
+			err = perr
+		}
+	}()
 
-	contents, err := ioutil.ReadDir(srcdir)
+	contents, err := src.ReadDir(srcdir)
 	if err != nil {
 		return err
 	}
 
+	if opt.Concurrency <= 0 {
+		for _, content := range contents {
+			cs, cd := src.Join(srcdir, content.Name()), filepath.Join(destdir, content.Name())
+			if err := copy(src, cs, cd, toSkip, sym, content, opt); err != nil {
+				// If any error, exit immediately
+				return err
+			}
+		}
+		return nil
+	}
+
+	return dcopyConcurrent(src, srcdir, destdir, toSkip, sym, contents, opt)
+}
+
+// dcopyConcurrent copies contents the same way dcopy's serial loop does,
+// except that every entry - including subdirectories, which recurse back
+// into dcopy from inside the pool - is handed to a bounded pool of
+// opt.Concurrency goroutines. That lets concurrency fan out across an
+// entire tree's worth of nested directories, not just the files sitting
+// directly inside whichever one is currently being visited. The first
+// error encountered cancels all outstanding pool jobs.
+func dcopyConcurrent(src source, srcdir, destdir string, toSkip map[string]struct{}, sym *symlinkState, contents []os.FileInfo, opt Options) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	jobs := make(chan os.FileInfo)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+
+	reportErr := func(err error) {
+		select {
+		case errs <- err:
+			cancel()
+		default:
+		}
+	}
+
+	for i := 0; i < opt.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for content := range jobs {
+				cs, cd := src.Join(srcdir, content.Name()), filepath.Join(destdir, content.Name())
+				if err := copy(src, cs, cd, toSkip, sym, content, opt); err != nil {
+					reportErr(err)
+					return
+				}
+			}
+		}()
+	}
+
+feed:
 	for _, content := range contents {
-		cs, cd := filepath.Join(srcdir, content.Name()), filepath.Join(destdir, content.Name())
-		if err := copy(cs, cd, toSkip, content, opt); err != nil {
-			// If any error, exit immediately
-			return err
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- content:
 		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	return nil
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
 }
 
-func onsymlink(src, dest string, info os.FileInfo, opt Options) error {
+func onsymlink(src source, srcPath, dest string, sym *symlinkState, info os.FileInfo, opt Options) error {
 
 	if opt.OnSymlink == nil {
 		opt.OnSymlink = DefaultOptions.OnSymlink
 	}
 
-	switch opt.OnSymlink(src) {
+	switch opt.OnSymlink(srcPath) {
 	case Shallow:
-		return lcopy(src, dest)
+		return lcopy(src, srcPath, dest, info, opt)
 	case Deep:
-		orig, err := os.Readlink(src)
+		orig, err := src.Readlink(srcPath)
 		if err != nil {
 			return err
 		}
-		info, err = os.Lstat(orig)
+		target, err := src.Lstat(orig)
+		if err != nil {
+			return err
+		}
+
+		key, err := symlinkKey(orig, target)
 		if err != nil {
 			return err
 		}
-		return copy(orig, dest, nil, info, opt)
+		if cyclic, depthExhausted := sym.descend(key); cyclic || depthExhausted {
+			if opt.OnSymlinkCycle == OnSymlinkCycleError {
+				if cyclic {
+					return ErrSymlinkCycle
+				}
+				return ErrMaxDepth
+			}
+			return lcopy(src, srcPath, dest, info, opt)
+		}
+		return copy(src, orig, dest, nil, sym, target, opt)
 	case Skip:
 		fallthrough
 	default:
@@ -139,18 +264,27 @@ func onsymlink(src, dest string, info os.FileInfo, opt Options) error {
 }
 
 // lcopy is for a symlink,
-// with just creating a new symlink by replicating src symlink.
-func lcopy(src, dest string) error {
-	src, err := os.Readlink(src)
+// with just creating a new symlink by replicating src symlink. Times and
+// xattrs aren't replicated for symlinks - os.Chtimes follows the link on
+// most platforms, so there's no portable way to set them on the link
+// itself - but ownership is, since os.Lchown doesn't follow it.
+func lcopy(src source, srcPath, dest string, info os.FileInfo, opt Options) error {
+	orig, err := src.Readlink(srcPath)
 	if err != nil {
 		return err
 	}
-	return os.Symlink(src, dest)
+	if err := os.Symlink(orig, dest); err != nil {
+		return err
+	}
+	if opt.Preserve.Has(PreserveOwner) {
+		return lchown(dest, info)
+	}
+	return nil
 }
 
 // fclose ANYHOW closes file,
 // with asiging error occured BUT respecting the error already reported.
-func fclose(f *os.File, reported *error) {
+func fclose(f io.Closer, reported *error) {
 	if err := f.Close(); *reported == nil {
 		*reported = err
 	}
@@ -0,0 +1,92 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopy_PreCallback_ErrSkip(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "dest")
+
+	writeFile(t, filepath.Join(src, "keep.txt"), "keep")
+	writeFile(t, filepath.Join(src, "skip.txt"), "skip")
+
+	var seen []string
+	opt := Options{
+		PreCallback: func(src, dest string, info os.FileInfo) error {
+			if filepath.Base(src) == "skip.txt" {
+				return ErrSkip
+			}
+			seen = append(seen, filepath.Base(src))
+			return nil
+		},
+	}
+
+	if err := Copy(src, dest, opt); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "keep.txt")); err != nil {
+		t.Errorf("expected keep.txt to be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "skip.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected skip.txt to be skipped, stat err = %v", err)
+	}
+}
+
+func TestCopy_PreCallback_OtherErrorAborts(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "dest")
+	writeFile(t, filepath.Join(src, "a.txt"), "a")
+
+	boom := os.ErrPermission
+	opt := Options{
+		PreCallback: func(src, dest string, info os.FileInfo) error {
+			return boom
+		},
+	}
+
+	if err := Copy(src, dest, opt); err != boom {
+		t.Fatalf("expected PreCallback error to abort copy, got %v", err)
+	}
+}
+
+func TestCopy_PostCallback(t *testing.T) {
+	src := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "dest")
+	writeFile(t, filepath.Join(src, "a.txt"), "a")
+
+	var calls []string
+	opt := Options{
+		PostCallback: func(src, dest string, info os.FileInfo) error {
+			calls = append(calls, filepath.Base(dest))
+			return nil
+		},
+	}
+
+	if err := Copy(src, dest, opt); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	found := false
+	for _, c := range calls {
+		if c == "a.txt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected PostCallback to fire for a.txt, got %v", calls)
+	}
+}
@@ -0,0 +1,108 @@
+package copy
+
+import "os"
+
+// Options specifies optional actions on copying.
+type Options struct {
+	// OnSymlink can specify what to do on symlink.
+	OnSymlink func(src string) SymlinkAction
+
+	// PreCallback is called before a file, directory, or symlink at src
+	// is copied to dest. If it returns ErrSkip, that entry (and, for a
+	// directory, everything beneath it) is skipped without error. Any
+	// other non-nil error aborts the whole copy.
+	PreCallback func(src, dest string, info os.FileInfo) error
+
+	// PostCallback is called after a file, directory, or symlink at src
+	// has been copied to dest. A non-nil error aborts the copy.
+	PostCallback func(src, dest string, info os.FileInfo) error
+
+	// UnpackArchives controls whether archive files encountered during the
+	// copy are extracted into dest instead of being copied byte-for-byte.
+	// The zero value, UnpackDisabled, preserves the historical behavior of
+	// copying archives as regular files.
+	UnpackArchives UnpackArchiveFormat
+
+	// Concurrency is the number of entries a directory copy may handle in
+	// parallel. The zero value keeps the historical serial behavior. Each
+	// subdirectory fans out its own contents across a pool of this same
+	// size, so the number of goroutines alive at once is bounded per
+	// directory, not globally - pick a value with that in mind for trees
+	// that nest deeply.
+	Concurrency int
+
+	// BufferSize is the size, in bytes, of the buffer used to copy file
+	// contents via io.CopyBuffer. The zero value lets io.Copy choose.
+	BufferSize int
+
+	// Preserve selects which extra filesystem metadata - beyond the file
+	// mode, which is always replicated - is copied from src to dest.
+	Preserve PreserveFlags
+
+	// MaxSymlinkDepth caps how many Deep-mode symlinks may be followed in
+	// total during one copy: every follow, in any part of the tree, uses up
+	// one from the same budget. The zero value means unlimited, relying
+	// solely on cycle detection to keep Deep mode from looping forever.
+	MaxSymlinkDepth int
+
+	// OnSymlinkCycle controls what happens when Deep mode hits a symlink
+	// cycle or exceeds MaxSymlinkDepth.
+	OnSymlinkCycle SymlinkCyclePolicy
+}
+
+// PreserveFlags is a bitmask of filesystem metadata to replicate from src
+// to dest, for cp -a-like semantics.
+type PreserveFlags int
+
+const (
+	// PreserveTimes replicates the source's modification time onto dest,
+	// and its access time on Linux; on other platforms dest's access time
+	// is set to the source's modification time.
+	PreserveTimes PreserveFlags = 1 << iota
+	// PreserveOwner replicates the source's uid and gid onto dest.
+	PreserveOwner
+	// PreserveXattr replicates the source's extended attributes onto dest,
+	// where the platform supports them.
+	PreserveXattr
+)
+
+// Has reports whether every bit set in want is also set in p.
+func (p PreserveFlags) Has(want PreserveFlags) bool {
+	return p&want == want
+}
+
+// UnpackArchiveFormat selects which archive formats Options.UnpackArchives
+// recognizes by file extension.
+type UnpackArchiveFormat int
+
+const (
+	// UnpackDisabled copies archive files byte-for-byte, like any other
+	// regular file. This is the zero value.
+	UnpackDisabled UnpackArchiveFormat = iota
+	// UnpackAuto extracts both zip and tar archives (.zip, .tar, .tar.gz, .tgz).
+	UnpackAuto
+	// UnpackZip only extracts .zip archives.
+	UnpackZip
+	// UnpackTar only extracts .tar, .tar.gz, and .tgz archives.
+	UnpackTar
+)
+
+// SymlinkAction represents what to do with symlinks.
+type SymlinkAction int
+
+const (
+	// Deep follows the symlink and copies whatever it points to.
+	Deep SymlinkAction = iota
+	// Shallow creates a new symlink pointing at the same target.
+	Shallow
+	// Skip does nothing with the symlink.
+	Skip
+)
+
+// DefaultOptions provides default options, which copies symlinks shallowly
+// and runs no callbacks.
+var DefaultOptions = Options{
+	OnSymlink: func(string) SymlinkAction {
+		return Shallow
+	},
+}
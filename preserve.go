@@ -0,0 +1,29 @@
+package copy
+
+import "os"
+
+// preserve replicates the metadata selected by opt.Preserve from the
+// source file or directory at srcPath onto dest, once dest's content has
+// already been written by fcopy or dcopy.
+func preserve(srcPath, dest string, info os.FileInfo, opt Options) error {
+	if opt.Preserve == 0 {
+		return nil
+	}
+
+	if opt.Preserve.Has(PreserveTimes) {
+		if err := os.Chtimes(dest, statAtime(info), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	if opt.Preserve.Has(PreserveOwner) {
+		if err := lchown(dest, info); err != nil {
+			return err
+		}
+	}
+	if opt.Preserve.Has(PreserveXattr) {
+		if err := copyXattr(srcPath, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
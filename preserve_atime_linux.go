@@ -0,0 +1,19 @@
+//go:build linux
+
+package copy
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statAtime returns the source's access time from its syscall.Stat_t,
+// falling back to its modification time when that isn't available.
+func statAtime(info os.FileInfo) time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+	return time.Unix(stat.Atim.Sec, stat.Atim.Nsec)
+}
@@ -0,0 +1,14 @@
+//go:build unix && !linux
+
+package copy
+
+import (
+	"os"
+	"time"
+)
+
+// statAtime isn't implemented for Darwin/the BSDs yet - syscall.Stat_t's
+// access-time field is named Atimespec there rather than Atim, and varies
+// further across that family - so it falls back to the modification time,
+// same as non-unix platforms.
+func statAtime(info os.FileInfo) time.Time { return info.ModTime() }
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package copy
+
+import (
+	"os"
+	"time"
+)
+
+// statAtime has no portable equivalent outside syscall.Stat_t, so it just
+// falls back to the modification time.
+func statAtime(info os.FileInfo) time.Time { return info.ModTime() }
+
+// lchown is a no-op: this platform isn't handled by the unix-specific
+// implementation.
+func lchown(dest string, info os.FileInfo) error { return nil }
+
+// copyXattr is a no-op: this platform isn't handled by the unix-specific
+// implementation.
+func copyXattr(src, dest string) error { return nil }
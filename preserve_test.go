@@ -0,0 +1,56 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopy_PreserveTimes(t *testing.T) {
+	src := t.TempDir()
+	srcFile := filepath.Join(src, "a.txt")
+	writeFile(t, srcFile, "a")
+
+	mtime := time.Date(2010, time.January, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(srcFile, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	if err := Copy(src, dest, Options{Preserve: PreserveTimes}); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "a.txt"))
+	if err != nil {
+		t.Fatalf("a.txt not copied: %v", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("dest ModTime = %v, want %v", info.ModTime(), mtime)
+	}
+}
+
+func TestCopy_WithoutPreserve_DoesNotKeepSourceTimes(t *testing.T) {
+	src := t.TempDir()
+	srcFile := filepath.Join(src, "a.txt")
+	writeFile(t, srcFile, "a")
+
+	old := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(srcFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	if err := Copy(src, dest); err != nil {
+		t.Fatalf("Copy returned error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "a.txt"))
+	if err != nil {
+		t.Fatalf("a.txt not copied: %v", err)
+	}
+	if info.ModTime().Equal(old) {
+		t.Errorf("dest ModTime unexpectedly matches source's old mtime without Options.Preserve")
+	}
+}
@@ -0,0 +1,77 @@
+//go:build unix
+
+package copy
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// statAtime is implemented per-GOOS in preserve_atime_*.go: syscall.Stat_t's
+// access-time field has a different name on Linux (Atim) than on Darwin and
+// the BSDs (Atimespec), so it can't live here under the broader "unix"
+// build constraint.
+
+// lchown replicates the source's uid and gid onto dest, without following
+// dest if it is itself a symlink.
+func lchown(dest string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return os.Lchown(dest, int(stat.Uid), int(stat.Gid))
+}
+
+// copyXattr replicates every extended attribute set on src onto dest.
+func copyXattr(src, dest string) error {
+	size, err := unix.Listxattr(src, nil)
+	if err != nil {
+		if err == unix.ENOTSUP {
+			return nil
+		}
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	n, err := unix.Listxattr(src, buf)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range splitXattrNames(buf[:n]) {
+		valueSize, err := unix.Getxattr(src, name, nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, valueSize)
+		if _, err := unix.Getxattr(src, name, value); err != nil {
+			return err
+		}
+		if err := unix.Setxattr(dest, name, value, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitXattrNames splits the NUL-separated attribute name list returned by
+// Listxattr into individual names.
+func splitXattrNames(buf []byte) []string {
+	var names []string
+	start := 0
+	for i, b := range buf {
+		if b != 0 {
+			continue
+		}
+		if i > start {
+			names = append(names, string(buf[start:i]))
+		}
+		start = i + 1
+	}
+	return names
+}
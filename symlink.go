@@ -0,0 +1,69 @@
+package copy
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSymlinkCycle is returned as the copy's error when a Deep-mode symlink
+// resolves back to a target already visited earlier in the same copy, and
+// Options.OnSymlinkCycle is OnSymlinkCycleError.
+var ErrSymlinkCycle = errors.New("copy: symlink cycle detected")
+
+// ErrMaxDepth is returned when following a Deep-mode symlink would exceed
+// Options.MaxSymlinkDepth, and Options.OnSymlinkCycle is
+// OnSymlinkCycleError.
+var ErrMaxDepth = errors.New("copy: max symlink depth exceeded")
+
+// SymlinkCyclePolicy controls what happens when Deep mode hits a symlink
+// cycle or exhausts Options.MaxSymlinkDepth.
+type SymlinkCyclePolicy int
+
+const (
+	// OnSymlinkCycleFallback copies the offending symlink shallowly
+	// instead of following it, so a cycle or a depth cap doesn't abort an
+	// otherwise-successful copy. This is the zero value.
+	OnSymlinkCycleFallback SymlinkCyclePolicy = iota
+	// OnSymlinkCycleError aborts the copy with ErrSymlinkCycle or
+	// ErrMaxDepth.
+	OnSymlinkCycleError
+)
+
+// symlinkState tracks, for a single top-level copy, which symlink targets
+// Deep mode has already followed - to detect cycles - and how many more
+// Deep follows remain under Options.MaxSymlinkDepth.
+type symlinkState struct {
+	mu      sync.Mutex
+	visited map[string]struct{}
+	depth   int // remaining hops allowed; negative means unlimited
+}
+
+func newSymlinkState(maxDepth int) *symlinkState {
+	depth := -1
+	if maxDepth > 0 {
+		depth = maxDepth
+	}
+	return &symlinkState{visited: make(map[string]struct{}), depth: depth}
+}
+
+// descend reports whether following key would revisit an already-seen
+// target (a cycle) or exceed the depth budget. When it reports false for
+// both, key is marked visited and the depth budget is consumed; otherwise
+// neither is touched, since the follow won't happen.
+func (s *symlinkState) descend(key string) (cyclic, depthExhausted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, seen := s.visited[key]; seen {
+		return true, false
+	}
+	if s.depth == 0 {
+		return false, true
+	}
+
+	s.visited[key] = struct{}{}
+	if s.depth > 0 {
+		s.depth--
+	}
+	return false, false
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package copy
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// symlinkKey has no (device, inode) pair to key off outside syscall.Stat_t,
+// so it falls back to the resolved absolute path.
+func symlinkKey(path string, info os.FileInfo) (string, error) {
+	return filepath.Abs(path)
+}
@@ -0,0 +1,72 @@
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func alwaysDeep(string) SymlinkAction { return Deep }
+
+func TestCopy_SymlinkCycle_FallbackToShallow(t *testing.T) {
+	root := t.TempDir()
+	loop := filepath.Join(root, "loop")
+	if err := os.Symlink(loop, loop); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "loop-copy")
+	opt := Options{OnSymlink: alwaysDeep}
+
+	if err := Copy(loop, dest, opt); err != nil {
+		t.Fatalf("expected self-referential symlink to fall back to Shallow, got error: %v", err)
+	}
+
+	info, err := os.Lstat(dest)
+	if err != nil {
+		t.Fatalf("dest not created: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected dest to be a symlink, mode = %v", info.Mode())
+	}
+}
+
+func TestCopy_SymlinkCycle_ErrorPolicy(t *testing.T) {
+	root := t.TempDir()
+	loop := filepath.Join(root, "loop")
+	if err := os.Symlink(loop, loop); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "loop-copy")
+	opt := Options{OnSymlink: alwaysDeep, OnSymlinkCycle: OnSymlinkCycleError}
+
+	err := Copy(loop, dest, opt)
+	if !errors.Is(err, ErrSymlinkCycle) {
+		t.Fatalf("expected ErrSymlinkCycle, got %v", err)
+	}
+}
+
+func TestCopy_MaxSymlinkDepth_ErrorPolicy(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target.txt")
+	writeFile(t, target, "content")
+
+	link2 := filepath.Join(root, "link2")
+	if err := os.Symlink(target, link2); err != nil {
+		t.Fatal(err)
+	}
+	link1 := filepath.Join(root, "link1")
+	if err := os.Symlink(link2, link1); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "link-copy")
+	opt := Options{OnSymlink: alwaysDeep, MaxSymlinkDepth: 1, OnSymlinkCycle: OnSymlinkCycleError}
+
+	err := Copy(link1, dest, opt)
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Fatalf("expected ErrMaxDepth after following 1 of 2 hops, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package copy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// symlinkKey identifies a Deep-mode symlink target by its (device, inode)
+// pair, so two different paths pointing at the same underlying file are
+// recognized as the same node during cycle detection.
+func symlinkKey(path string, info os.FileInfo) (string, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return filepath.Abs(path)
+	}
+	return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino), nil
+}
@@ -0,0 +1,96 @@
+package copy
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// source abstracts the filesystem that src is read from, so that copy can
+// walk something other than the real OS filesystem: an embed.FS, a zip
+// archive opened via zip.Reader (both implement fs.FS), or any other
+// in-memory filesystem.
+type source interface {
+	Lstat(name string) (os.FileInfo, error)
+	Open(name string) (fs.File, error)
+	ReadDir(name string) ([]os.FileInfo, error)
+	Readlink(name string) (string, error)
+	// Join joins dir and name into a path suitable for this source's other
+	// methods. osSource joins with the OS separator; fsSource must always
+	// join with a forward slash, per fs.ValidPath, regardless of GOOS.
+	Join(dir, name string) string
+}
+
+// osSource is the default source, backed directly by the OS filesystem.
+// It is what Copy and CopyButSkipSome use.
+type osSource struct{}
+
+func (osSource) Lstat(name string) (os.FileInfo, error) { return os.Lstat(name) }
+
+func (osSource) Open(name string) (fs.File, error) { return os.Open(name) }
+
+func (osSource) ReadDir(name string) ([]os.FileInfo, error) { return ioutil.ReadDir(name) }
+
+func (osSource) Readlink(name string) (string, error) { return os.Readlink(name) }
+
+func (osSource) Join(dir, name string) string { return filepath.Join(dir, name) }
+
+// fsSource adapts an fs.FS into a source. fs.FS has no notion of symlinks,
+// so Readlink always fails; trees coming from an fs.FS are assumed to
+// contain none.
+type fsSource struct {
+	fsys fs.FS
+}
+
+func (s fsSource) Lstat(name string) (os.FileInfo, error) { return fs.Stat(s.fsys, name) }
+
+func (s fsSource) Open(name string) (fs.File, error) { return s.fsys.Open(name) }
+
+func (s fsSource) ReadDir(name string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (s fsSource) Readlink(name string) (string, error) {
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+func (s fsSource) Join(dir, name string) string { return path.Join(dir, name) }
+
+// CopyFS copies srcPath out of srcFS into dest on the real filesystem.
+// This lets callers materialize a tree embedded in their binary (via
+// embed.FS), packed in a zip or tar opened as an fs.FS, or held in any
+// other virtual filesystem.
+func CopyFS(srcFS fs.FS, srcPath, dest string, opt ...Options) error {
+	return copyFSButSkipSome(srcFS, srcPath, dest, nil, opt...)
+}
+
+func copyFSButSkipSome(srcFS fs.FS, srcPath, dest string, toSkip []string, opt ...Options) error {
+	toSkipMap := make(map[string]struct{})
+	for i := 0; i < len(toSkip); i++ {
+		toSkipMap[toSkip[i]] = struct{}{}
+	}
+
+	opt = append(opt, DefaultOptions)
+	src := fsSource{fsys: srcFS}
+	info, err := src.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	sym := newSymlinkState(opt[0].MaxSymlinkDepth)
+	return copy(src, srcPath, dest, toSkipMap, sym, info, opt[0])
+}
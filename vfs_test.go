@@ -0,0 +1,36 @@
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCopyFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"tmpl/README.md":    {Data: []byte("hello"), Mode: 0o644},
+		"tmpl/nested/a.txt": {Data: []byte("a"), Mode: 0o644},
+	}
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	if err := CopyFS(fsys, "tmpl", dest); err != nil {
+		t.Fatalf("CopyFS returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dest, "README.md"))
+	if err != nil {
+		t.Fatalf("README.md not copied: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("README.md content = %q, want %q", got, "hello")
+	}
+
+	got, err = os.ReadFile(filepath.Join(dest, "nested", "a.txt"))
+	if err != nil {
+		t.Fatalf("nested/a.txt not copied: %v", err)
+	}
+	if string(got) != "a" {
+		t.Errorf("nested/a.txt content = %q, want %q", got, "a")
+	}
+}